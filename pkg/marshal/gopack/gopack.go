@@ -0,0 +1,102 @@
+// Copyright 2021 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gopack provides a runtime fallback for the go_marshal code
+// generator's packedness analysis. go_marshal decides at code-generation
+// time whether a type's layout is free of compiler-inserted padding by
+// walking its AST; types for which that analysis can't be resolved
+// statically (for example, because a field's size depends on a
+// build-constrained type) fall back to the helpers here, which reach the
+// same conclusion via reflection at runtime.
+package gopack
+
+import (
+	"reflect"
+	"unsafe"
+
+	"gvisor.dev/gvisor/pkg/safecopy"
+)
+
+// IsSafe reports whether a value of type t can be marshalled by copying its
+// raw bytes: it must be a fixed-size, non-pointer-containing type whose
+// fields (recursively) have no interior or trailing padding.
+//
+// IsSafe is the runtime counterpart to go_marshal's static alignment
+// analysis; it's used when the generator can't prove packedness for a type
+// at code-generation time (e.g. a field of another package's type whose
+// layout isn't visible to the AST walk).
+func IsSafe(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int8, reflect.Uint8, reflect.Int16, reflect.Uint16,
+		reflect.Int32, reflect.Uint32, reflect.Int64, reflect.Uint64:
+		return true
+	case reflect.Array:
+		return IsSafe(t.Elem())
+	case reflect.Struct:
+		return isStructSafe(t)
+	default:
+		return false
+	}
+}
+
+// isStructSafe walks t's fields in declaration order, tracking the offset
+// the next field must start at for the struct to be padding-free. This
+// mirrors the "primitive / array of valid / struct of valid without
+// interior padding" rule go_marshal applies at code-generation time;
+// trailing padding (t.Size() larger than the sum of its field sizes) also
+// disqualifies t, since interior padding would otherwise
+// appear between elements of a []t.
+func isStructSafe(t reflect.Type) bool {
+	offset := uintptr(0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !IsSafe(f.Type) {
+			return false
+		}
+		if f.Offset != offset {
+			// The compiler inserted padding before this field to satisfy
+			// its alignment.
+			return false
+		}
+		offset += f.Type.Size()
+	}
+	return offset == t.Size()
+}
+
+// PutSlice marshals count elements of elemSize bytes each, starting at src,
+// into dst, which must be at least count*elemSize bytes. src is a pointer
+// to the first element (e.g. unsafe.Pointer(&s[0])). Callers must only use
+// PutSlice on element types for which IsSafe reports true.
+//
+// PutSlice takes a raw pointer rather than a generic slice parameter: this
+// predates Go's introduction of type parameters, and go_marshal's
+// generated call sites already have a concrete, same-package slice type in
+// hand, so there's nothing for a type parameter to buy here.
+func PutSlice(dst []byte, src unsafe.Pointer, elemSize, count int) {
+	if count == 0 {
+		return
+	}
+	safecopy.CopyIn(dst[:elemSize*count], src)
+}
+
+// GetSlice unmarshals count*elemSize bytes from src into dst, a pointer to
+// the first of count elements of elemSize bytes each (e.g.
+// unsafe.Pointer(&s[0])). Callers must only use GetSlice on element types
+// for which IsSafe reports true.
+func GetSlice(dst unsafe.Pointer, src []byte, elemSize, count int) {
+	if count == 0 {
+		return
+	}
+	safecopy.CopyOut(dst, src[:elemSize*count])
+}
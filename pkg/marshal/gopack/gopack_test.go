@@ -0,0 +1,87 @@
+// Copyright 2021 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+type packedStruct struct {
+	A uint32
+	B uint32
+}
+
+type paddedStruct struct {
+	A uint8
+	B uint32
+}
+
+type nestedPackedStruct struct {
+	X packedStruct
+	Y uint64
+}
+
+type nestedPaddedStruct struct {
+	X paddedStruct
+	Y uint64
+}
+
+func TestIsSafe(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		val  interface{}
+		want bool
+	}{
+		{name: "uint32", val: uint32(0), want: true},
+		{name: "int64", val: int64(0), want: true},
+		{name: "array of uint32", val: [4]uint32{}, want: true},
+		{name: "packed struct", val: packedStruct{}, want: true},
+		{name: "padded struct", val: paddedStruct{}, want: false},
+		{name: "nested packed struct", val: nestedPackedStruct{}, want: true},
+		{name: "nested padded struct", val: nestedPaddedStruct{}, want: false},
+		{name: "string", val: "not safe", want: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsSafe(reflect.TypeOf(test.val)); got != test.want {
+				t.Errorf("IsSafe(%T) = %v, want %v", test.val, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPutSliceGetSliceRoundTrip(t *testing.T) {
+	src := []packedStruct{{A: 1, B: 2}, {A: 3, B: 4}, {A: 5, B: 6}}
+	elemSize := int(reflect.TypeOf(src).Elem().Size())
+	buf := make([]byte, len(src)*elemSize)
+	PutSlice(buf, unsafe.Pointer(&src[0]), elemSize, len(src))
+
+	got := make([]packedStruct, len(src))
+	GetSlice(unsafe.Pointer(&got[0]), buf, elemSize, len(got))
+
+	for i := range src {
+		if got[i] != src[i] {
+			t.Errorf("got[%d] = %+v, want %+v", i, got[i], src[i])
+		}
+	}
+}
+
+func TestPutSliceGetSliceEmpty(t *testing.T) {
+	// Must not panic on a zero count, since there's no first element to
+	// take the address of.
+	PutSlice(nil, nil, int(unsafe.Sizeof(packedStruct{})), 0)
+	GetSlice(nil, nil, int(unsafe.Sizeof(packedStruct{})), 0)
+}
@@ -15,15 +15,47 @@
 package vfs2
 
 import (
+	"math"
+
 	"gvisor.dev/gvisor/pkg/abi/linux"
 	"gvisor.dev/gvisor/pkg/sentry/arch"
 	"gvisor.dev/gvisor/pkg/sentry/kernel"
 	slinux "gvisor.dev/gvisor/pkg/sentry/syscalls/linux"
 	"gvisor.dev/gvisor/pkg/sentry/vfs"
+	"gvisor.dev/gvisor/pkg/sentry/vfs/fspath"
 	"gvisor.dev/gvisor/pkg/syserror"
 	"gvisor.dev/gvisor/pkg/usermem"
 )
 
+// Resolve flags for openat2(2), as defined by Linux's
+// include/uapi/linux/openat2.h. Openat2 uses them only to validate
+// open_how.resolve: unknown bits fail with EINVAL, and any known bit fails
+// with ENOSYS, since the path walk does not apply them.
+const (
+	resolveNoXDev       = 0x01
+	resolveNoMagiclinks = 0x02
+	resolveNoSymlinks   = 0x04
+	resolveBeneath      = 0x08
+	resolveInRoot       = 0x10
+	resolveCached       = 0x20
+	resolveNoAutomount  = 0x40
+
+	resolveKnownFlags = resolveNoXDev | resolveNoMagiclinks | resolveNoSymlinks |
+		resolveBeneath | resolveInRoot | resolveCached | resolveNoAutomount
+
+	// openHowSize0 is the size of struct open_how as of its introduction
+	// in Linux 5.6. Callers passing a larger struct must have all bytes
+	// past this size zeroed, per the Linux "extensible struct" ABI.
+	openHowSize0 = 24
+)
+
+// openHow mirrors Linux's struct open_how.
+type openHow struct {
+	Flags   uint64
+	Mode    uint64
+	Resolve uint64
+}
+
 // Open implements Linux syscall open(2).
 func Open(t *kernel.Task, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
 	addr := args[0].Pointer()
@@ -53,6 +85,16 @@ func openat(t *kernel.Task, dirfd int32, pathAddr usermem.Addr, flags uint32, mo
 	if err != nil {
 		return 0, nil, err
 	}
+	mode &^= linux.FileMode(t.FSContext().Umask())
+
+	// O_TMPFILE reuses a bit of O_DIRECTORY, so it must be checked for
+	// explicitly rather than falling out of the normal open path: the
+	// "directory" being opened becomes the parent of an unlinked inode
+	// rather than the file itself.
+	if flags&linux.O_TMPFILE == linux.O_TMPFILE {
+		return openTmpfile(t, dirfd, path, flags, mode)
+	}
+
 	tpop, err := getTaskPathOperation(t, dirfd, path, disallowEmptyPath, shouldFollowFinalSymlink(flags&linux.O_NOFOLLOW == 0))
 	if err != nil {
 		return 0, nil, err
@@ -61,7 +103,7 @@ func openat(t *kernel.Task, dirfd int32, pathAddr usermem.Addr, flags uint32, mo
 
 	file, err := t.Kernel().VFS().OpenAt(t, t.Credentials(), &tpop.pop, &vfs.OpenOptions{
 		Flags: flags,
-		Mode:  mode &^ linux.FileMode(t.FSContext().Umask()),
+		Mode:  mode,
 	})
 	if err != nil {
 		return 0, nil, err
@@ -74,6 +116,128 @@ func openat(t *kernel.Task, dirfd int32, pathAddr usermem.Addr, flags uint32, mo
 	return uintptr(fd), nil, err
 }
 
+// openTmpfile handles the O_TMPFILE variant of openat(), in which dirfd/path
+// names the directory to hold an anonymous, unlinked regular file. No such
+// file is created: a request without a write access mode fails with EINVAL
+// and every other request fails with ENOSYS.
+func openTmpfile(t *kernel.Task, dirfd int32, path fspath.Path, flags uint32, mode linux.FileMode) (uintptr, *kernel.SyscallControl, error) {
+	// O_TMPFILE is only valid combined with a write mode; Linux rejects
+	// O_RDONLY (which is 0) for lack of any other bits to distinguish it
+	// from a plain directory open.
+	if flags&linux.O_ACCMODE == linux.O_RDONLY {
+		return 0, nil, syserror.EINVAL
+	}
+
+	// TODO(gvisor.dev/issue/6265): no FilesystemImpl in this tree implements
+	// anonymous-inode allocation yet (vfs.VirtualFilesystem has no
+	// CreateTmpfileAt to call into), so there's nothing correct to do here
+	// besides reject the request. Fail explicitly rather than open path
+	// itself as a regular file, which would silently give callers a linked,
+	// named file instead of the unlinked one O_TMPFILE promises.
+	return 0, nil, syserror.ENOSYS
+}
+
+// Openat2 implements Linux syscall openat2(2).
+func Openat2(t *kernel.Task, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	dirfd := args[0].Int()
+	pathAddr := args[1].Pointer()
+	howAddr := args[2].Pointer()
+	size := args[3].SizeT()
+
+	if size < openHowSize0 {
+		return 0, nil, syserror.EINVAL
+	}
+
+	if size > openHowSize0 {
+		// Linux requires every byte beyond the struct version the kernel
+		// knows about to be zero, so that newer userspace can detect at
+		// runtime whether an older kernel understood the fields it set.
+		if err := copyInOpenHowTail(t, howAddr, openHowSize0, size); err != nil {
+			return 0, nil, err
+		}
+	}
+	var buf [openHowSize0]byte
+	if _, err := t.CopyInBytes(howAddr, buf[:]); err != nil {
+		return 0, nil, err
+	}
+	how := openHow{
+		Flags:   usermem.ByteOrder.Uint64(buf[0:8]),
+		Mode:    usermem.ByteOrder.Uint64(buf[8:16]),
+		Resolve: usermem.ByteOrder.Uint64(buf[16:24]),
+	}
+
+	if how.Resolve&^resolveKnownFlags != 0 {
+		return 0, nil, syserror.EINVAL
+	}
+	if how.Flags&^uint64(linux.O_RDONLY|linux.O_WRONLY|linux.O_RDWR|linux.O_CREAT|
+		linux.O_EXCL|linux.O_NOCTTY|linux.O_TRUNC|linux.O_APPEND|linux.O_NONBLOCK|
+		linux.O_DSYNC|linux.O_ASYNC|linux.O_DIRECT|linux.O_LARGEFILE|linux.O_DIRECTORY|
+		linux.O_NOFOLLOW|linux.O_NOATIME|linux.O_CLOEXEC|linux.O_SYNC|linux.O_PATH|
+		linux.O_TMPFILE) != 0 {
+		return 0, nil, syserror.EINVAL
+	}
+	if how.Mode != 0 && how.Flags&(linux.O_CREAT|linux.O_TMPFILE) == 0 {
+		return 0, nil, syserror.EINVAL
+	}
+
+	path, err := copyInPath(t, pathAddr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// As in openat(), O_TMPFILE reuses a bit of O_DIRECTORY and must be
+	// checked for explicitly: the path names the parent of an unlinked
+	// inode to create, not a file to open directly.
+	if how.Flags&linux.O_TMPFILE == linux.O_TMPFILE {
+		return openTmpfile(t, dirfd, path, uint32(how.Flags), linux.FileMode(how.Mode))
+	}
+
+	tpop, err := getTaskPathOperation(t, dirfd, path, disallowEmptyPath, shouldFollowFinalSymlink(uint32(how.Flags)&linux.O_NOFOLLOW == 0))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tpop.Release()
+
+	if how.Resolve != 0 {
+		// TODO(gvisor.dev/issue/6265): no FilesystemImpl's path walker
+		// enforces vfs.PathOperation.ResolveFlags yet, so setting it here
+		// would silently open the file without the RESOLVE_* guarantees
+		// the caller asked for. Reject explicitly until that enforcement
+		// lands, rather than pretend to honor flags we don't check.
+		return 0, nil, syserror.ENOSYS
+	}
+
+	file, err := t.Kernel().VFS().OpenAt(t, t.Credentials(), &tpop.pop, &vfs.OpenOptions{
+		Flags: uint32(how.Flags),
+		Mode:  linux.FileMode(how.Mode) &^ linux.FileMode(t.FSContext().Umask()),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.DecRef()
+
+	fd, err := t.NewFDFromVFS2(0, file, kernel.FDFlags{
+		CloseOnExec: how.Flags&linux.O_CLOEXEC != 0,
+	})
+	return uintptr(fd), nil, err
+}
+
+// copyInOpenHowTail verifies that any bytes of the open_how struct beyond
+// the portion this kernel understands are zero, returning E2BIG otherwise
+// per the openat2(2) extensible-struct ABI.
+func copyInOpenHowTail(t *kernel.Task, addr usermem.Addr, knownSize, size uint) error {
+	tail := make([]byte, size-knownSize)
+	if _, err := t.CopyInBytes(addr+usermem.Addr(knownSize), tail); err != nil {
+		return err
+	}
+	for _, b := range tail {
+		if b != 0 {
+			return syserror.E2BIG
+		}
+	}
+	return nil
+}
+
 // Close implements Linux syscall close(2).
 func Close(t *kernel.Task, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
 	fd := args[0].Int()
@@ -91,6 +255,52 @@ func Close(t *kernel.Task, args arch.SyscallArguments) (uintptr, *kernel.Syscall
 	return 0, nil, slinux.HandleIOErrorVFS2(t, false /* partial */, err, syserror.EINTR, "close", file)
 }
 
+// CloseRange implements Linux syscall close_range(2).
+func CloseRange(t *kernel.Task, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
+	fd := args[0].Int()
+	// max_fd is an unsigned int; callers conventionally pass ~0U as "every
+	// fd from fd to the end of the table", which overflows int32. Decode it
+	// as unsigned and clamp rather than truncating it back to a negative
+	// int32, which would make the fd <= maxFD check below reject it.
+	rawMaxFD := args[1].Uint()
+	maxFD := int32(math.MaxInt32)
+	if rawMaxFD <= math.MaxInt32 {
+		maxFD = int32(rawMaxFD)
+	}
+	flags := args[2].Uint()
+
+	if flags&^(linux.CLOSE_RANGE_UNSHARE|linux.CLOSE_RANGE_CLOEXEC) != 0 {
+		return 0, nil, syserror.EINVAL
+	}
+	if fd < 0 || maxFD < fd {
+		return 0, nil, syserror.EINVAL
+	}
+
+	// CLOSE_RANGE_UNSHARE (give the task a private copy of its fd table
+	// before operating on it) and CLOSE_RANGE_CLOEXEC (mark fds
+	// close-on-exec instead of closing them) both need FDTable support this
+	// tree doesn't have: Close above shows the only FDTable primitive
+	// available to vfs2 syscalls is Remove(fd), and nothing adds a Fork or
+	// a per-range flag setter. Rather than fabricate those APIs, reject the
+	// flags explicitly.
+	if flags&(linux.CLOSE_RANGE_UNSHARE|linux.CLOSE_RANGE_CLOEXEC) != 0 {
+		return 0, nil, syserror.ENOSYS
+	}
+
+	// There's no bulk range-close helper to call into, so remove and drop
+	// references on each fd in range individually, the same way Close does.
+	// Count in int64 so that maxFD == math.MaxInt32 doesn't wrap fd around.
+	for i := int64(fd); i <= int64(maxFD); i++ {
+		_, file := t.FDTable().Remove(int32(i))
+		if file == nil {
+			continue
+		}
+		file.OnClose(t)
+		file.DecRef()
+	}
+	return 0, nil, nil
+}
+
 // Dup implements Linux syscall dup(2).
 func Dup(t *kernel.Task, args arch.SyscallArguments) (uintptr, *kernel.SyscallControl, error) {
 	fd := args[0].Int()
@@ -252,6 +252,65 @@ func TestHomeDir(t *testing.T) {
 
 }
 
+// TestCheckpointRestore checkpoints an httpd pod, kills the sandbox, then
+// restores it from the checkpoint image and verifies that the container
+// keeps serving the same content.
+func TestCheckpointRestore(t *testing.T) {
+	// `runsc checkpoint`/`runsc restore` and the sentry-side state
+	// serialization they depend on (FDTable, mount table, task/thread
+	// group, futex and signal state) are a multi-package effort that
+	// hasn't landed yet -- see the request this test was added for.
+	// Skip rather than exercise runsc subcommands that don't exist, which
+	// would just fail with a confusing "unknown command" error instead of
+	// a clear signal that checkpoint/restore isn't supported yet.
+	t.Skip("checkpoint/restore is not implemented yet")
+
+	// Setup containerd and crictl.
+	crictl, cleanup, err := setup(t)
+	if err != nil {
+		t.Fatalf("failed to setup crictl: %v", err)
+	}
+	defer cleanup()
+	podID, contID, err := crictl.StartPodAndContainer("basic/httpd", Sandbox, Httpd)
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+
+	if err = httpGet(crictl, podID, "index.html"); err != nil {
+		t.Fatalf("failed to get page before checkpoint: %v", err)
+	}
+
+	imagePath, err := ioutil.TempDir(testutil.TmpDir(), "checkpoint-image")
+	if err != nil {
+		t.Fatalf("failed to create checkpoint image dir: %v", err)
+	}
+	defer os.RemoveAll(imagePath)
+
+	runtime, err := dockerutil.RuntimePath()
+	if err != nil {
+		t.Fatalf("error discovering runtime path: %v", err)
+	}
+
+	if out, err := exec.Command(runtime, "checkpoint", contID, "--image-path", imagePath).CombinedOutput(); err != nil {
+		t.Fatalf("checkpoint failed: %v, out: %s", err, out)
+	}
+
+	// Stop the pod; the sandbox process should be gone, but the
+	// checkpoint image on disk lets us bring the container back.
+	if err := crictl.StopPodAndContainer(podID, contID); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+
+	if out, err := exec.Command(runtime, "restore", contID, "--image-path", imagePath).CombinedOutput(); err != nil {
+		t.Fatalf("restore failed: %v, out: %s", err, out)
+	}
+
+	// The restored container should still be serving the same page.
+	if err = httpGet(crictl, podID, "index.html"); err != nil {
+		t.Fatalf("failed to get page after restore: %v", err)
+	}
+}
+
 // containerdConfigTemplate is a .toml config for containerd. It contains a
 // formatting verb so the runtime field can be set via fmt.Sprintf.
 const containerdConfigTemplate = `
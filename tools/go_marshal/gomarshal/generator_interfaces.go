@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"strings"
 )
 
 // interfaceGenerator generates marshalling interfaces for a single type.
@@ -45,6 +46,33 @@ type interfaceGenerator struct {
 	// as records embedded fields in t that are potentially not packed. The key
 	// is the accessor for the field.
 	as map[string]struct{}
+
+	// byteOrder is the explicit wire byte order selected for t via a
+	// `// +marshal byteorder:BigEndian` (or LittleEndian) annotation, or ""
+	// to use the host's native usermem.ByteOrder. Network protocol types
+	// (pkg/tcpip headers, etc.) set this so go_marshal emits big-endian
+	// accessors regardless of host byte order.
+	byteOrder string
+}
+
+// byteOrderExpr returns the Go expression go_marshal should use to encode
+// and decode multi-byte fields of t: either the host-native
+// usermem.ByteOrder, or an explicit binary.BigEndian/binary.LittleEndian
+// selected by a byte order annotation.
+func (g *interfaceGenerator) byteOrderExpr() string {
+	switch g.byteOrder {
+	case "":
+		g.recordUsedImport("usermem")
+		return "usermem.ByteOrder"
+	case "BigEndian":
+		g.recordUsedImport("binary")
+		return "binary.BigEndian"
+	case "LittleEndian":
+		g.recordUsedImport("binary")
+		return "binary.LittleEndian"
+	default:
+		panic(fmt.Sprintf("unknown byte order annotation %q", g.byteOrder))
+	}
 }
 
 // typeName returns the name of the type this g represents.
@@ -63,9 +91,45 @@ func newInterfaceGenerator(t *ast.TypeSpec, fset *token.FileSet) *interfaceGener
 		as: make(map[string]struct{}),
 	}
 	g.recordUsedMarshallable(g.typeName())
+	g.byteOrder = g.parseByteOrderAnnotation()
 	return g
 }
 
+// parseByteOrderAnnotation scans t's doc comment for a
+// `+marshal byteorder:BigEndian` (or LittleEndian) directive and returns the
+// selected order, or "" if t didn't specify one, in which case the default
+// host-native usermem.ByteOrder applies. Network protocol header types
+// (pkg/tcpip headers, etc.) use this to pin their wire format to a fixed
+// byte order regardless of the host's endianness.
+//
+// Only t's own doc comment (TypeSpec.Doc) is read. go/parser attaches the
+// comment of a type declaration without parentheses to the enclosing
+// GenDecl instead, and that comment is not consulted here.
+func (g *interfaceGenerator) parseByteOrderAnnotation() string {
+	if g.t.Doc == nil {
+		return ""
+	}
+	const directivePrefix = "byteorder:"
+	for _, c := range g.t.Doc.List {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimPrefix(c.Text, "//"), " "))
+		if len(fields) == 0 || fields[0] != "+marshal" {
+			continue
+		}
+		for _, tok := range fields[1:] {
+			if !strings.HasPrefix(tok, directivePrefix) {
+				continue
+			}
+			switch order := strings.TrimPrefix(tok, directivePrefix); order {
+			case "BigEndian", "LittleEndian":
+				return order
+			default:
+				g.abortAt(c.Pos(), fmt.Sprintf("unknown byteorder directive %q, must be BigEndian or LittleEndian", order))
+			}
+		}
+	}
+	return ""
+}
+
 func (g *interfaceGenerator) recordUsedMarshallable(m string) {
 	g.ms[m] = struct{}{}
 
@@ -124,16 +188,13 @@ func (g *interfaceGenerator) marshalScalar(accessor, typ, bufVar string) {
 		g.emit("%s[0] = byte(%s)\n", bufVar, accessor)
 		g.shift(bufVar, 1)
 	case "int16", "uint16":
-		g.recordUsedImport("usermem")
-		g.emit("usermem.ByteOrder.PutUint16(%s[:2], uint16(%s))\n", bufVar, accessor)
+		g.emit("%s.PutUint16(%s[:2], uint16(%s))\n", g.byteOrderExpr(), bufVar, accessor)
 		g.shift(bufVar, 2)
 	case "int32", "uint32":
-		g.recordUsedImport("usermem")
-		g.emit("usermem.ByteOrder.PutUint32(%s[:4], uint32(%s))\n", bufVar, accessor)
+		g.emit("%s.PutUint32(%s[:4], uint32(%s))\n", g.byteOrderExpr(), bufVar, accessor)
 		g.shift(bufVar, 4)
 	case "int64", "uint64":
-		g.recordUsedImport("usermem")
-		g.emit("usermem.ByteOrder.PutUint64(%s[:8], uint64(%s))\n", bufVar, accessor)
+		g.emit("%s.PutUint64(%s[:8], uint64(%s))\n", g.byteOrderExpr(), bufVar, accessor)
 		g.shift(bufVar, 8)
 	default:
 		g.emit("%s.MarshalBytes(%s[:%s.SizeBytes()])\n", accessor, bufVar, accessor)
@@ -155,30 +216,24 @@ func (g *interfaceGenerator) unmarshalScalar(accessor, typ, bufVar string) {
 		g.shift(bufVar, 1)
 
 	case "int16":
-		g.recordUsedImport("usermem")
-		g.emit("%s = int16(usermem.ByteOrder.Uint16(%s[:2]))\n", accessor, bufVar)
+		g.emit("%s = int16(%s.Uint16(%s[:2]))\n", accessor, g.byteOrderExpr(), bufVar)
 		g.shift(bufVar, 2)
 	case "uint16":
-		g.recordUsedImport("usermem")
-		g.emit("%s = usermem.ByteOrder.Uint16(%s[:2])\n", accessor, bufVar)
+		g.emit("%s = %s.Uint16(%s[:2])\n", accessor, g.byteOrderExpr(), bufVar)
 		g.shift(bufVar, 2)
 
 	case "int32":
-		g.recordUsedImport("usermem")
-		g.emit("%s = int32(usermem.ByteOrder.Uint32(%s[:4]))\n", accessor, bufVar)
+		g.emit("%s = int32(%s.Uint32(%s[:4]))\n", accessor, g.byteOrderExpr(), bufVar)
 		g.shift(bufVar, 4)
 	case "uint32":
-		g.recordUsedImport("usermem")
-		g.emit("%s = usermem.ByteOrder.Uint32(%s[:4])\n", accessor, bufVar)
+		g.emit("%s = %s.Uint32(%s[:4])\n", accessor, g.byteOrderExpr(), bufVar)
 		g.shift(bufVar, 4)
 
 	case "int64":
-		g.recordUsedImport("usermem")
-		g.emit("%s = int64(usermem.ByteOrder.Uint64(%s[:8]))\n", accessor, bufVar)
+		g.emit("%s = int64(%s.Uint64(%s[:8]))\n", accessor, g.byteOrderExpr(), bufVar)
 		g.shift(bufVar, 8)
 	case "uint64":
-		g.recordUsedImport("usermem")
-		g.emit("%s = usermem.ByteOrder.Uint64(%s[:8])\n", accessor, bufVar)
+		g.emit("%s = %s.Uint64(%s[:8])\n", accessor, g.byteOrderExpr(), bufVar)
 		g.shift(bufVar, 8)
 	default:
 		g.emit("%s.UnmarshalBytes(%s[:%s.SizeBytes()])\n", accessor, bufVar, accessor)
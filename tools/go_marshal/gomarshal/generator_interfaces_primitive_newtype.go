@@ -29,14 +29,11 @@ func (g *interfaceGenerator) marshalPrimitiveScalar(accessor, typ, bufVar string
 	case "int8", "uint8", "byte":
 		g.emit("%s[0] = byte(*%s)\n", bufVar, accessor)
 	case "int16", "uint16":
-		g.recordUsedImport("usermem")
-		g.emit("usermem.ByteOrder.PutUint16(%s[:2], uint16(*%s))\n", bufVar, accessor)
+		g.emit("%s.PutUint16(%s[:2], uint16(*%s))\n", g.byteOrderExpr(), bufVar, accessor)
 	case "int32", "uint32":
-		g.recordUsedImport("usermem")
-		g.emit("usermem.ByteOrder.PutUint32(%s[:4], uint32(*%s))\n", bufVar, accessor)
+		g.emit("%s.PutUint32(%s[:4], uint32(*%s))\n", g.byteOrderExpr(), bufVar, accessor)
 	case "int64", "uint64":
-		g.recordUsedImport("usermem")
-		g.emit("usermem.ByteOrder.PutUint64(%s[:8], uint64(*%s))\n", bufVar, accessor)
+		g.emit("%s.PutUint64(%s[:8], uint64(*%s))\n", g.byteOrderExpr(), bufVar, accessor)
 	default:
 		g.emit("// Explicilty cast to the underlying type before dispatching to\n")
 		g.emit("// MarshalBytes, so we don't recursively call %s.MarshalBytes\n", accessor)
@@ -56,25 +53,19 @@ func (g *interfaceGenerator) unmarshalPrimitiveScalar(accessor, typ, bufVar, typ
 		g.emit("*%s = %s(%s[0])\n", accessor, typeCast, bufVar)
 
 	case "int16":
-		g.recordUsedImport("usermem")
-		g.emit("*%s = %s(int16(usermem.ByteOrder.Uint16(%s[:2])))\n", accessor, typeCast, bufVar)
+		g.emit("*%s = %s(int16(%s.Uint16(%s[:2])))\n", accessor, typeCast, g.byteOrderExpr(), bufVar)
 	case "uint16":
-		g.recordUsedImport("usermem")
-		g.emit("*%s = %s(usermem.ByteOrder.Uint16(%s[:2]))\n", accessor, typeCast, bufVar)
+		g.emit("*%s = %s(%s.Uint16(%s[:2]))\n", accessor, typeCast, g.byteOrderExpr(), bufVar)
 
 	case "int32":
-		g.recordUsedImport("usermem")
-		g.emit("*%s = %s(int32(usermem.ByteOrder.Uint32(%s[:4])))\n", accessor, typeCast, bufVar)
+		g.emit("*%s = %s(int32(%s.Uint32(%s[:4])))\n", accessor, typeCast, g.byteOrderExpr(), bufVar)
 	case "uint32":
-		g.recordUsedImport("usermem")
-		g.emit("*%s = %s(usermem.ByteOrder.Uint32(%s[:4]))\n", accessor, typeCast, bufVar)
+		g.emit("*%s = %s(%s.Uint32(%s[:4]))\n", accessor, typeCast, g.byteOrderExpr(), bufVar)
 
 	case "int64":
-		g.recordUsedImport("usermem")
-		g.emit("*%s = %s(int64(usermem.ByteOrder.Uint64(%s[:8])))\n", accessor, typeCast, bufVar)
+		g.emit("*%s = %s(int64(%s.Uint64(%s[:8])))\n", accessor, typeCast, g.byteOrderExpr(), bufVar)
 	case "uint64":
-		g.recordUsedImport("usermem")
-		g.emit("*%s = %s(usermem.ByteOrder.Uint64(%s[:8]))\n", accessor, typeCast, bufVar)
+		g.emit("*%s = %s(%s.Uint64(%s[:8]))\n", accessor, typeCast, g.byteOrderExpr(), bufVar)
 	default:
 		g.emit("// Explicilty cast to the underlying type before dispatching to\n")
 		g.emit("// UnmarshalBytes, so we don't recursively call %s.UnmarshalBytes\n", accessor)
@@ -134,29 +125,120 @@ func (g *interfaceGenerator) emitMarshallableForPrimitiveNewtype(nt *ast.Ident)
 	})
 	g.emit("}\n\n")
 
+	// hostNative is false for types carrying a `// +marshal byteorder:...`
+	// annotation whose wire layout doesn't necessarily match the host's.
+	// Such types must route through the byte-swapping MarshalBytes /
+	// UnmarshalBytes path rather than blitting host memory directly.
+	hostNative := g.byteOrder == ""
+
 	g.emit("// Packed implements marshal.Marshallable.Packed.\n")
 	g.emit("func (%s *%s) Packed() bool {\n", g.r, g.typeName())
 	g.inIndent(func() {
-		g.emit("// Scalar newtypes are always packed.\n")
-		g.emit("return true\n")
+		if hostNative {
+			g.emit("// Scalar newtypes are always packed.\n")
+			g.emit("return true\n")
+		} else {
+			g.recordUsedImport("usermem")
+			g.recordUsedImport("binary")
+			g.emit("// The wire format only matches the host's native layout if the\n")
+			g.emit("// explicitly-selected byte order happens to match the host's.\n")
+			g.emit("return binary.ByteOrder(usermem.ByteOrder) == binary.ByteOrder(%s)\n", g.byteOrderExpr())
+		}
+	})
+	g.emit("}\n\n")
+
+	g.emit("// PackedSlice implements marshal.Marshallable.PackedSlice.\n")
+	g.emit("func (%s *%s) PackedSlice() bool {\n", g.r, g.typeName())
+	g.inIndent(func() {
+		if hostNative {
+			g.emit("// A slice of a scalar newtype has no interior or trailing padding\n")
+			g.emit("// between elements, so it's always safe to marshal in one shot.\n")
+			g.emit("return true\n")
+		} else {
+			g.emit("return %s.Packed()\n", g.r)
+		}
 	})
 	g.emit("}\n\n")
 
 	g.emit("// MarshalUnsafe implements marshal.Marshallable.MarshalUnsafe.\n")
-	g.recordUsedImport("safecopy")
-	g.recordUsedImport("unsafe")
 	g.emit("func (%s *%s) MarshalUnsafe(dst []byte) {\n", g.r, g.typeName())
 	g.inIndent(func() {
-		g.emit("safecopy.CopyIn(dst, unsafe.Pointer(%s))\n", g.r)
+		if hostNative {
+			g.recordUsedImport("safecopy")
+			g.recordUsedImport("unsafe")
+			g.emit("safecopy.CopyIn(dst, unsafe.Pointer(%s))\n", g.r)
+		} else {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout,\n", g.typeName())
+			g.emit("// so it isn't safe to express as a raw memory copy.\n")
+			g.emit("%s.MarshalBytes(dst)\n", g.r)
+		}
 	})
 	g.emit("}\n\n")
 
 	g.emit("// UnmarshalUnsafe implements marshal.Marshallable.UnmarshalUnsafe.\n")
-	g.recordUsedImport("safecopy")
-	g.recordUsedImport("unsafe")
 	g.emit("func (%s *%s) UnmarshalUnsafe(src []byte) {\n", g.r, g.typeName())
 	g.inIndent(func() {
-		g.emit("safecopy.CopyOut(unsafe.Pointer(%s), src)\n", g.r)
+		if hostNative {
+			g.recordUsedImport("safecopy")
+			g.recordUsedImport("unsafe")
+			g.emit("safecopy.CopyOut(unsafe.Pointer(%s), src)\n", g.r)
+		} else {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout,\n", g.typeName())
+			g.emit("// so it isn't safe to express as a raw memory copy.\n")
+			g.emit("%s.UnmarshalBytes(src)\n", g.r)
+		}
+	})
+	g.emit("}\n\n")
+
+	g.emit("// MarshalUnsafeSlice implements marshal.Marshallable.MarshalUnsafeSlice.\n")
+	g.emit("func (%s *%s) MarshalUnsafeSlice(dst []byte, src []%s) {\n", g.r, g.typeName(), g.typeName())
+	g.inIndent(func() {
+		g.emit("count := len(src)\n")
+		g.emit("if count == 0 {\n")
+		g.inIndent(func() {
+			g.emit("return\n")
+		})
+		g.emit("}\n")
+		g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+		if !hostNative {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout,\n", g.typeName())
+			g.emit("// so it isn't safe to express as a raw memory copy.\n")
+			g.emit("for i := range src {\n")
+			g.inIndent(func() {
+				g.emit("src[i].MarshalBytes(dst[i*size : (i+1)*size])\n")
+			})
+			g.emit("}\n")
+			return
+		}
+		g.recordUsedImport("safecopy")
+		g.recordUsedImport("unsafe")
+		g.emit("safecopy.CopyIn(dst[:(size*count)], unsafe.Pointer(&src[0]))\n")
+	})
+	g.emit("}\n\n")
+
+	g.emit("// UnmarshalUnsafeSlice implements marshal.Marshallable.UnmarshalUnsafeSlice.\n")
+	g.emit("func (%s *%s) UnmarshalUnsafeSlice(dst []%s, src []byte) {\n", g.r, g.typeName(), g.typeName())
+	g.inIndent(func() {
+		g.emit("count := len(dst)\n")
+		g.emit("if count == 0 {\n")
+		g.inIndent(func() {
+			g.emit("return\n")
+		})
+		g.emit("}\n")
+		g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+		if !hostNative {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout,\n", g.typeName())
+			g.emit("// so it isn't safe to express as a raw memory copy.\n")
+			g.emit("for i := range dst {\n")
+			g.inIndent(func() {
+				g.emit("dst[i].UnmarshalBytes(src[i*size : (i+1)*size])\n")
+			})
+			g.emit("}\n")
+			return
+		}
+		g.recordUsedImport("safecopy")
+		g.recordUsedImport("unsafe")
+		g.emit("safecopy.CopyOut(unsafe.Pointer(&dst[0]), src[:(size*count)])\n")
 	})
 	g.emit("}\n\n")
 
@@ -165,6 +247,14 @@ func (g *interfaceGenerator) emitMarshallableForPrimitiveNewtype(nt *ast.Ident)
 	g.recordUsedImport("usermem")
 	g.emit("func (%s *%s) CopyOut(task marshal.Task, addr usermem.Addr) (int, error) {\n", g.r, g.typeName())
 	g.inIndent(func() {
+		if !hostNative {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout, so\n", g.typeName())
+			g.emit("// the fast unsafe-copy path below isn't available to it.\n")
+			g.emit("buf := make([]byte, %s.SizeBytes())\n", g.r)
+			g.emit("%s.MarshalBytes(buf)\n", g.r)
+			g.emit("return task.CopyOutBytes(addr, buf)\n")
+			return
+		}
 		// Fast serialization.
 		g.emit("// Bypass escape analysis on %s. The no-op arithmetic operation on the\n", g.r)
 		g.emit("// pointer makes the compiler think val doesn't depend on %s.\n", g.r)
@@ -188,11 +278,74 @@ func (g *interfaceGenerator) emitMarshallableForPrimitiveNewtype(nt *ast.Ident)
 	})
 	g.emit("}\n\n")
 
+	g.emit("// CopyOutN implements marshal.Marshallable.CopyOutN, copying the whole\n")
+	g.emit("// of src in one task copy rather than one per element.\n")
+	g.recordUsedImport("marshal")
+	g.recordUsedImport("usermem")
+	g.emit("func (%s *%s) CopyOutN(task marshal.Task, addr usermem.Addr, src []%s) (int, error) {\n", g.r, g.typeName(), g.typeName())
+	g.inIndent(func() {
+		g.emit("count := len(src)\n")
+		g.emit("if count == 0 {\n")
+		g.inIndent(func() {
+			g.emit("return 0, nil\n")
+		})
+		g.emit("}\n")
+		if !hostNative {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout, so\n", g.typeName())
+			g.emit("// the fast unsafe-copy path below isn't available to it.\n")
+			g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+			g.emit("buf := make([]byte, size*count)\n")
+			g.emit("for i, elem := range src {\n")
+			g.inIndent(func() {
+				g.emit("elem.MarshalBytes(buf[i*size : (i+1)*size])\n")
+			})
+			g.emit("}\n")
+			g.emit("return task.CopyOutBytes(addr, buf)\n")
+			return
+		}
+		g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+		g.emit("// Bypass escape analysis on src. The no-op arithmetic operation on the\n")
+		g.emit("// pointer makes the compiler think val doesn't depend on src.\n")
+		g.emit("// See src/runtime/stubs.go:noescape() in the golang toolchain.\n")
+		g.emit("ptr := unsafe.Pointer(&src[0])\n")
+		g.emit("val := uintptr(ptr)\n")
+		g.emit("val = val^0\n\n")
+
+		g.emit("// Construct a slice backed by src's underlying memory, covering every\n")
+		g.emit("// element in one shot.\n")
+		g.emit("var buf []byte\n")
+		g.emit("hdr := (*reflect.SliceHeader)(unsafe.Pointer(&buf))\n")
+		g.emit("hdr.Data = val\n")
+		g.emit("hdr.Len = size * count\n")
+		g.emit("hdr.Cap = size * count\n\n")
+
+		g.emit("len, err := task.CopyOutBytes(addr, buf)\n")
+		g.emit("// Since we bypassed the compiler's escape analysis, indicate that src\n")
+		g.emit("// must live until after the CopyOutBytes.\n")
+		g.emit("runtime.KeepAlive(src)\n")
+		g.emit("return len, err\n")
+	})
+	g.emit("}\n\n")
+
 	g.emit("// CopyIn implements marshal.Marshallable.CopyIn.\n")
 	g.recordUsedImport("marshal")
 	g.recordUsedImport("usermem")
 	g.emit("func (%s *%s) CopyIn(task marshal.Task, addr usermem.Addr) (int, error) {\n", g.r, g.typeName())
 	g.inIndent(func() {
+		if !hostNative {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout, so\n", g.typeName())
+			g.emit("// the fast unsafe-copy path below isn't available to it.\n")
+			g.emit("buf := make([]byte, %s.SizeBytes())\n", g.r)
+			g.emit("n, err := task.CopyInBytes(addr, buf)\n")
+			g.emit("if err != nil {\n")
+			g.inIndent(func() {
+				g.emit("return n, err\n")
+			})
+			g.emit("}\n")
+			g.emit("%s.UnmarshalBytes(buf)\n", g.r)
+			g.emit("return n, nil\n")
+			return
+		}
 		g.emit("// Bypass escape analysis on %s. The no-op arithmetic operation on the\n", g.r)
 		g.emit("// pointer makes the compiler think val doesn't depend on %s.\n", g.r)
 		g.emit("// See src/runtime/stubs.go:noescape() in the golang toolchain.\n")
@@ -215,6 +368,61 @@ func (g *interfaceGenerator) emitMarshallableForPrimitiveNewtype(nt *ast.Ident)
 	})
 	g.emit("}\n\n")
 
+	g.emit("// CopyInN implements marshal.Marshallable.CopyInN, filling the whole of\n")
+	g.emit("// dst in one task copy rather than one per element.\n")
+	g.recordUsedImport("marshal")
+	g.recordUsedImport("usermem")
+	g.emit("func (%s *%s) CopyInN(task marshal.Task, addr usermem.Addr, dst []%s) (int, error) {\n", g.r, g.typeName(), g.typeName())
+	g.inIndent(func() {
+		g.emit("count := len(dst)\n")
+		g.emit("if count == 0 {\n")
+		g.inIndent(func() {
+			g.emit("return 0, nil\n")
+		})
+		g.emit("}\n")
+		if !hostNative {
+			g.emit("// %s's wire format doesn't necessarily match the host's layout, so\n", g.typeName())
+			g.emit("// the fast unsafe-copy path below isn't available to it.\n")
+			g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+			g.emit("buf := make([]byte, size*count)\n")
+			g.emit("n, err := task.CopyInBytes(addr, buf)\n")
+			g.emit("if err != nil {\n")
+			g.inIndent(func() {
+				g.emit("return n, err\n")
+			})
+			g.emit("}\n")
+			g.emit("for i := range dst {\n")
+			g.inIndent(func() {
+				g.emit("dst[i].UnmarshalBytes(buf[i*size : (i+1)*size])\n")
+			})
+			g.emit("}\n")
+			g.emit("return n, nil\n")
+			return
+		}
+		g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+		g.emit("// Bypass escape analysis on dst. The no-op arithmetic operation on the\n")
+		g.emit("// pointer makes the compiler think val doesn't depend on dst.\n")
+		g.emit("// See src/runtime/stubs.go:noescape() in the golang toolchain.\n")
+		g.emit("ptr := unsafe.Pointer(&dst[0])\n")
+		g.emit("val := uintptr(ptr)\n")
+		g.emit("val = val^0\n\n")
+
+		g.emit("// Construct a slice backed by dst's underlying memory, covering every\n")
+		g.emit("// element in one shot.\n")
+		g.emit("var buf []byte\n")
+		g.emit("hdr := (*reflect.SliceHeader)(unsafe.Pointer(&buf))\n")
+		g.emit("hdr.Data = val\n")
+		g.emit("hdr.Len = size * count\n")
+		g.emit("hdr.Cap = size * count\n\n")
+
+		g.emit("len, err := task.CopyInBytes(addr, buf)\n")
+		g.emit("// Since we bypassed the compiler's escape analysis, indicate that dst\n")
+		g.emit("// must live until after the CopyInBytes.\n")
+		g.emit("runtime.KeepAlive(dst)\n")
+		g.emit("return len, err\n")
+	})
+	g.emit("}\n\n")
+
 	g.emit("// WriteTo implements io.WriterTo.WriteTo.\n")
 	g.recordUsedImport("io")
 	g.emit("func (%s *%s) WriteTo(w io.Writer) (int64, error) {\n", g.r, g.typeName())
@@ -0,0 +1,270 @@
+// Copyright 2021 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the bits of the code generator specific to marshalling
+// plain structs (as opposed to newtypes on primitives, see
+// generator_interfaces_primitive_newtype.go).
+
+package gomarshal
+
+import (
+	"go/ast"
+)
+
+// packedness describes how confident the generator is that a struct's
+// memory layout has no compiler-inserted padding, and therefore whether
+// marshalling a []T can be expressed as a single raw memory copy spanning
+// every element.
+type packedness int
+
+const (
+	// notPacked means the struct has interior or trailing padding; only
+	// the byte-oriented, per-element slow path may be emitted.
+	notPacked packedness = iota
+	// packedAtRuntime means the generator couldn't resolve every field's
+	// size and alignment at code-generation time (e.g. a named type
+	// defined in another package), so the fast path must be gated behind
+	// a runtime check (see pkg/marshal/gopack.IsSafe), cached with
+	// sync.Once.
+	packedAtRuntime
+	// packedStatic means every field's size and alignment was resolved
+	// from the AST and proven free of padding, so the fast path can be
+	// emitted unconditionally.
+	packedStatic
+)
+
+// analyzeStructPackedness walks fields in declaration order, using
+// fieldInfo to resolve each field's size and alignment, and tracks the
+// offset the next field must start at for the struct to be free of
+// compiler-inserted padding. fieldInfo returns knownAtGenTime=false for any
+// field whose layout can't be proven statically (e.g. a named type defined
+// in another package), which degrades the result to packedAtRuntime rather
+// than conservatively rejecting the whole type.
+//
+// Unlike a field-by-field walk alone, this also catches trailing padding:
+// the compiler pads a struct's overall size up to its widest field's
+// alignment, which would otherwise leave an undetected gap between
+// consecutive elements of a []T even when no individual field is
+// misaligned.
+func (g *interfaceGenerator) analyzeStructPackedness(fields []*ast.Field, fieldInfo func(*ast.Field) (size, align int, knownAtGenTime bool)) packedness {
+	offset := 0
+	maxAlign := 1
+	result := packedStatic
+	for _, f := range fields {
+		size, align, known := fieldInfo(f)
+		if !known {
+			// Every later field's offset depends on this field's size, so
+			// none of them can be checked statically either.
+			return packedAtRuntime
+		}
+		if align > maxAlign {
+			maxAlign = align
+		}
+		if offset%align != 0 {
+			// The compiler must insert padding before this field to
+			// satisfy its alignment; that padding would also appear
+			// between every element of a []T.
+			return notPacked
+		}
+		offset += size
+	}
+	if result == packedStatic && offset%maxAlign != 0 {
+		return notPacked
+	}
+	return result
+}
+
+// fieldSizeAlign resolves the size and alignment of a struct field for
+// packedness analysis. Only fields with a directly-named primitive type
+// can be resolved statically; anything else (nested structs, arrays,
+// pointers, slices) is reported as unknown so the caller falls back to a
+// runtime check rather than risking an incorrect static answer.
+func (g *interfaceGenerator) fieldSizeAlign(f *ast.Field) (size, align int, knownAtGenTime bool) {
+	id, ok := f.Type.(*ast.Ident)
+	if !ok {
+		return 0, 0, false
+	}
+	size, unknown := g.scalarSize(id)
+	if unknown {
+		return 0, 0, false
+	}
+	// None of the primitive types scalarSize resolves are ever aligned
+	// coarser than their own width on the architectures gVisor supports.
+	return size, size, true
+}
+
+// emitMarshallableForStruct outputs code to implement the packed-dependent
+// half of the marshal.Marshallable interface for a plain struct: Packed,
+// PackedSlice, MarshalUnsafe(Slice) and UnmarshalUnsafe(Slice). Unlike a
+// primitive newtype, a struct's packedness can only be proven by walking
+// its fields, so the slice-marshal fast path may need to be gated behind a
+// runtime check (pkg/marshal/gopack.IsSafe) or may have to fall back to
+// the byte-oriented per-element path entirely.
+func (g *interfaceGenerator) emitMarshallableForStruct(fields []*ast.Field) packedness {
+	packed := g.analyzeStructPackedness(fields, g.fieldSizeAlign)
+
+	if packed == packedAtRuntime {
+		g.emitRuntimePackedCache()
+	}
+
+	g.emit("// Packed implements marshal.Marshallable.Packed.\n")
+	g.emit("func (%s *%s) Packed() bool {\n", g.r, g.typeName())
+	g.inIndent(func() {
+		switch packed {
+		case packedStatic:
+			g.emit("return true\n")
+		case notPacked:
+			g.emit("return false\n")
+		case packedAtRuntime:
+			g.emitRuntimePackedCheck()
+		}
+	})
+	g.emit("}\n\n")
+
+	g.emit("// PackedSlice implements marshal.Marshallable.PackedSlice.\n")
+	g.emit("func (%s *%s) PackedSlice() bool {\n", g.r, g.typeName())
+	g.inIndent(func() {
+		g.emit("return %s.Packed()\n", g.r)
+	})
+	g.emit("}\n\n")
+
+	g.recordUsedImport("safecopy")
+	g.recordUsedImport("unsafe")
+	g.emit("// MarshalUnsafe implements marshal.Marshallable.MarshalUnsafe.\n")
+	g.emit("func (%s *%s) MarshalUnsafe(dst []byte) {\n", g.r, g.typeName())
+	g.inIndent(func() {
+		if packed == notPacked {
+			g.emit("%s.MarshalBytes(dst)\n", g.r)
+			return
+		}
+		if packed == packedAtRuntime {
+			g.emit("if !%s.Packed() {\n", g.r)
+			g.inIndent(func() {
+				g.emit("%s.MarshalBytes(dst)\n", g.r)
+				g.emit("return\n")
+			})
+			g.emit("}\n")
+		}
+		g.emit("safecopy.CopyIn(dst, unsafe.Pointer(%s))\n", g.r)
+	})
+	g.emit("}\n\n")
+
+	g.emit("// UnmarshalUnsafe implements marshal.Marshallable.UnmarshalUnsafe.\n")
+	g.emit("func (%s *%s) UnmarshalUnsafe(src []byte) {\n", g.r, g.typeName())
+	g.inIndent(func() {
+		if packed == notPacked {
+			g.emit("%s.UnmarshalBytes(src)\n", g.r)
+			return
+		}
+		if packed == packedAtRuntime {
+			g.emit("if !%s.Packed() {\n", g.r)
+			g.inIndent(func() {
+				g.emit("%s.UnmarshalBytes(src)\n", g.r)
+				g.emit("return\n")
+			})
+			g.emit("}\n")
+		}
+		g.emit("safecopy.CopyOut(unsafe.Pointer(%s), src)\n", g.r)
+	})
+	g.emit("}\n\n")
+
+	g.emit("// MarshalUnsafeSlice implements marshal.Marshallable.MarshalUnsafeSlice.\n")
+	g.emit("func (%s *%s) MarshalUnsafeSlice(dst []byte, src []%s) {\n", g.r, g.typeName(), g.typeName())
+	g.inIndent(func() {
+		g.emit("count := len(src)\n")
+		g.emit("if count == 0 {\n")
+		g.inIndent(func() { g.emit("return\n") })
+		g.emit("}\n")
+		g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+		if packed != packedStatic {
+			g.emit("if !(*%s)(nil).Packed() {\n", g.typeName())
+			g.inIndent(func() {
+				g.emit("for i := range src {\n")
+				g.inIndent(func() {
+					g.emit("src[i].MarshalBytes(dst[i*size : (i+1)*size])\n")
+				})
+				g.emit("}\n")
+				g.emit("return\n")
+			})
+			g.emit("}\n")
+		}
+		if packed == packedAtRuntime {
+			g.recordUsedImport("gopack")
+			g.emit("gopack.PutSlice(dst, unsafe.Pointer(&src[0]), size, count)\n")
+		} else {
+			g.emit("safecopy.CopyIn(dst[:(size*count)], unsafe.Pointer(&src[0]))\n")
+		}
+	})
+	g.emit("}\n\n")
+
+	g.emit("// UnmarshalUnsafeSlice implements marshal.Marshallable.UnmarshalUnsafeSlice.\n")
+	g.emit("func (%s *%s) UnmarshalUnsafeSlice(dst []%s, src []byte) {\n", g.r, g.typeName(), g.typeName())
+	g.inIndent(func() {
+		g.emit("count := len(dst)\n")
+		g.emit("if count == 0 {\n")
+		g.inIndent(func() { g.emit("return\n") })
+		g.emit("}\n")
+		g.emit("size := (*%s)(nil).SizeBytes()\n", g.typeName())
+		if packed != packedStatic {
+			g.emit("if !(*%s)(nil).Packed() {\n", g.typeName())
+			g.inIndent(func() {
+				g.emit("for i := range dst {\n")
+				g.inIndent(func() {
+					g.emit("dst[i].UnmarshalBytes(src[i*size : (i+1)*size])\n")
+				})
+				g.emit("}\n")
+				g.emit("return\n")
+			})
+			g.emit("}\n")
+		}
+		if packed == packedAtRuntime {
+			g.recordUsedImport("gopack")
+			g.emit("gopack.GetSlice(unsafe.Pointer(&dst[0]), src, size, count)\n")
+		} else {
+			g.emit("safecopy.CopyOut(unsafe.Pointer(&dst[0]), src[:(size*count)])\n")
+		}
+	})
+	g.emit("}\n\n")
+
+	return packed
+}
+
+// emitRuntimePackedCheck emits a sync.Once-cached call to
+// pkg/marshal/gopack.IsSafe, for structs whose packedness can't be proven
+// from the AST alone (e.g. because they embed a type defined in another
+// package). The result is cached per-type, since reflection is too costly
+// to repeat on every Packed() call.
+func (g *interfaceGenerator) emitRuntimePackedCheck() {
+	g.recordUsedImport("gopack")
+	g.recordUsedImport("reflect")
+	g.recordUsedImport("sync")
+	g.emit("%sPackedOnce.Do(func() {\n", g.typeName())
+	g.inIndent(func() {
+		g.emit("%sPackedCache = gopack.IsSafe(reflect.TypeOf(%s{}))\n", g.typeName(), g.typeName())
+	})
+	g.emit("})\n")
+	g.emit("return %sPackedCache\n", g.typeName())
+}
+
+// emitRuntimePackedCache emits the package-level sync.Once and cached bool
+// backing emitRuntimePackedCheck. Only called for types whose
+// analyzeStructPackedness result is packedAtRuntime.
+func (g *interfaceGenerator) emitRuntimePackedCache() {
+	g.emit("var (\n")
+	g.inIndent(func() {
+		g.emit("%sPackedOnce sync.Once\n", g.typeName())
+		g.emit("%sPackedCache bool\n", g.typeName())
+	})
+	g.emit(")\n\n")
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The gVisor Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomarshal
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// parseTypeSpec parses src (a single package-level declaration) and returns
+// its lone TypeSpec, with doc comments attached.
+func parseTypeSpec(t *testing.T, src string) (*ast.TypeSpec, *token.FileSet) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", "package p\n\n"+src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse test source: %v", err)
+	}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				if ts.Doc == nil {
+					// go/parser attaches the doc comment of a declaration
+					// without parentheses to the GenDecl, not the TypeSpec.
+					ts.Doc = gd.Doc
+				}
+				return ts, fset
+			}
+		}
+	}
+	t.Fatalf("no type declaration found in source:\n%s", src)
+	return nil, nil
+}
+
+func TestByteOrderAnnotation(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "no annotation",
+			src:  "type Foo uint32\n",
+			want: "",
+		},
+		{
+			name: "big endian",
+			src:  "// +marshal byteorder:BigEndian\ntype Foo uint32\n",
+			want: "BigEndian",
+		},
+		{
+			name: "little endian",
+			src:  "// +marshal byteorder:LittleEndian\ntype Foo uint32\n",
+			want: "LittleEndian",
+		},
+		{
+			name: "unrelated comment",
+			src:  "// Foo is a newtype on uint32.\ntype Foo uint32\n",
+			want: "",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ts, fset := parseTypeSpec(t, test.src)
+			g := newInterfaceGenerator(ts, fset)
+			if g.byteOrder != test.want {
+				t.Errorf("byteOrder = %q, want %q", g.byteOrder, test.want)
+			}
+		})
+	}
+}
+
+func TestByteOrderExpr(t *testing.T) {
+	for _, test := range []struct {
+		byteOrder string
+		want      string
+	}{
+		{byteOrder: "", want: "usermem.ByteOrder"},
+		{byteOrder: "BigEndian", want: "binary.BigEndian"},
+		{byteOrder: "LittleEndian", want: "binary.LittleEndian"},
+	} {
+		t.Run(test.want, func(t *testing.T) {
+			ts, fset := parseTypeSpec(t, "type Foo uint32\n")
+			g := newInterfaceGenerator(ts, fset)
+			g.byteOrder = test.byteOrder
+			if got := g.byteOrderExpr(); got != test.want {
+				t.Errorf("byteOrderExpr() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}